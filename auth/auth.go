@@ -0,0 +1,156 @@
+// Package auth issues and verifies RS256-signed JWTs for admin
+// authentication, replacing the static shared-secret Bearer check with
+// short-lived, role-scoped access tokens plus longer-lived refresh tokens.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// AccessTokenTTL is how long an issued access token stays valid.
+	AccessTokenTTL = 15 * time.Minute
+	// RefreshTokenTTL is how long an issued refresh token stays valid.
+	RefreshTokenTTL = 7 * 24 * time.Hour
+
+	// TokenTypeAccess marks a token as a short-lived access token.
+	TokenTypeAccess = "access"
+	// TokenTypeRefresh marks a token as a long-lived refresh token.
+	TokenTypeRefresh = "refresh"
+)
+
+// ErrInvalidToken is returned for any token that fails signature, expiry, or
+// type verification.
+var ErrInvalidToken = errors.New("auth: invalid token")
+
+// Claims are the JWT claims issued for an authenticated user.
+type Claims struct {
+	UserID uint   `json:"uid"`
+	Email  string `json:"email"`
+	Role   string `json:"role"`
+	Type   string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+// Manager issues and verifies RS256 JWTs using an RSA keypair loaded from
+// the configured PEM paths.
+type Manager struct {
+	privateKey *rsa.PrivateKey
+	publicKey  *rsa.PublicKey
+}
+
+// NewManager loads the RSA private key from privateKeyPath and derives the
+// public key from it (the file at publicKeyPath is not read), generating and
+// persisting a new 2048-bit keypair at both paths if the private key file
+// does not exist yet.
+func NewManager(privateKeyPath, publicKeyPath string) (*Manager, error) {
+	priv, pub, err := loadOrGenerateKeypair(privateKeyPath, publicKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	return &Manager{privateKey: priv, publicKey: pub}, nil
+}
+
+func loadOrGenerateKeypair(privPath, pubPath string) (*rsa.PrivateKey, *rsa.PublicKey, error) {
+	if _, err := os.Stat(privPath); err == nil {
+		return loadKeypair(privPath)
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, nil, fmt.Errorf("auth: generate keypair: %w", err)
+	}
+	if err := writeKeypair(key, privPath, pubPath); err != nil {
+		return nil, nil, err
+	}
+	return key, &key.PublicKey, nil
+}
+
+func loadKeypair(privPath string) (*rsa.PrivateKey, *rsa.PublicKey, error) {
+	privPEM, err := os.ReadFile(privPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("auth: read private key: %w", err)
+	}
+	block, _ := pem.Decode(privPEM)
+	if block == nil {
+		return nil, nil, fmt.Errorf("auth: invalid private key PEM at %s", privPath)
+	}
+	priv, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("auth: parse private key: %w", err)
+	}
+	return priv, &priv.PublicKey, nil
+}
+
+func writeKeypair(key *rsa.PrivateKey, privPath, pubPath string) error {
+	privBytes := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	})
+	if err := os.WriteFile(privPath, privBytes, 0o600); err != nil {
+		return fmt.Errorf("auth: write private key: %w", err)
+	}
+
+	pubBytes := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PUBLIC KEY",
+		Bytes: x509.MarshalPKCS1PublicKey(&key.PublicKey),
+	})
+	if err := os.WriteFile(pubPath, pubBytes, 0o644); err != nil {
+		return fmt.Errorf("auth: write public key: %w", err)
+	}
+	return nil
+}
+
+// IssueAccessToken returns a short-lived JWT asserting the user's id, email, and role.
+func (m *Manager) IssueAccessToken(userID uint, email, role string) (string, error) {
+	return m.issue(userID, email, role, TokenTypeAccess, AccessTokenTTL)
+}
+
+// IssueRefreshToken returns a long-lived JWT used only to mint new access tokens.
+func (m *Manager) IssueRefreshToken(userID uint, email, role string) (string, error) {
+	return m.issue(userID, email, role, TokenTypeRefresh, RefreshTokenTTL)
+}
+
+func (m *Manager) issue(userID uint, email, role, typ string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := Claims{
+		UserID: userID,
+		Email:  email,
+		Role:   role,
+		Type:   typ,
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	return token.SignedString(m.privateKey)
+}
+
+// Verify parses and validates tokenString, returning its claims if it is a
+// well-formed, unexpired token of expectedType signed by this Manager.
+func (m *Manager) Verify(tokenString, expectedType string) (*Claims, error) {
+	var claims Claims
+	token, err := jwt.ParseWithClaims(tokenString, &claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, fmt.Errorf("auth: unexpected signing method %v", t.Header["alg"])
+		}
+		return m.publicKey, nil
+	})
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+	if claims.Type != expectedType {
+		return nil, ErrInvalidToken
+	}
+	return &claims, nil
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+	dir := t.TempDir()
+	m, err := NewManager(filepath.Join(dir, "priv.pem"), filepath.Join(dir, "pub.pem"))
+	if err != nil {
+		t.Fatalf("NewManager returned error: %v", err)
+	}
+	return m
+}
+
+func TestIssueAndVerifyAccessToken(t *testing.T) {
+	m := newTestManager(t)
+
+	token, err := m.IssueAccessToken(1, "admin@example.com", "admin")
+	if err != nil {
+		t.Fatalf("IssueAccessToken returned error: %v", err)
+	}
+
+	claims, err := m.Verify(token, TokenTypeAccess)
+	if err != nil {
+		t.Fatalf("Verify returned error: %v", err)
+	}
+	if claims.UserID != 1 || claims.Email != "admin@example.com" || claims.Role != "admin" {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+}
+
+func TestVerifyRejectsWrongTokenType(t *testing.T) {
+	m := newTestManager(t)
+
+	refreshToken, err := m.IssueRefreshToken(1, "admin@example.com", "admin")
+	if err != nil {
+		t.Fatalf("IssueRefreshToken returned error: %v", err)
+	}
+
+	if _, err := m.Verify(refreshToken, TokenTypeAccess); err != ErrInvalidToken {
+		t.Fatalf("expected ErrInvalidToken, got %v", err)
+	}
+}
+
+func TestVerifyRejectsTokenFromDifferentManager(t *testing.T) {
+	m1 := newTestManager(t)
+	m2 := newTestManager(t)
+
+	token, err := m1.IssueAccessToken(1, "admin@example.com", "admin")
+	if err != nil {
+		t.Fatalf("IssueAccessToken returned error: %v", err)
+	}
+
+	if _, err := m2.Verify(token, TokenTypeAccess); err != ErrInvalidToken {
+		t.Fatalf("expected ErrInvalidToken, got %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/machidevdev/dorado-be/httperr"
+)
+
+// ContextUserKey is the Gin context key RequireRole stores the verified
+// claims under.
+const ContextUserKey = "authUser"
+
+// RequireRole returns Gin middleware that rejects the request unless the
+// Authorization header carries a valid, unexpired access token for a user
+// with the given role. On success the token's claims are attached to the
+// request context under ContextUserKey.
+func RequireRole(manager *Manager, role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			httperr.Abort(c, 401, httperr.CodeUnauthorized, "missing authorization header", "")
+			return
+		}
+
+		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+		if tokenString == authHeader {
+			httperr.Abort(c, 401, httperr.CodeUnauthorized, "malformed authorization header", "")
+			return
+		}
+
+		claims, err := manager.Verify(tokenString, TokenTypeAccess)
+		if err != nil || claims.Role != role {
+			httperr.Abort(c, 401, httperr.CodeUnauthorized, "invalid or insufficient token", "")
+			return
+		}
+
+		c.Set(ContextUserKey, claims)
+		c.Next()
+	}
+}
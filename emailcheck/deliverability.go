@@ -0,0 +1,190 @@
+// Package emailcheck provides deliverability and disposable-domain checks
+// that go beyond the syntactic email validation already done at signup.
+package emailcheck
+
+import (
+	"container/list"
+	"context"
+	"net"
+	"sync"
+	"time"
+)
+
+// PositiveTTL and NegativeTTL control how long resolver results are cached,
+// keyed by domain. Negative results expire sooner so a domain that starts
+// accepting mail is picked up reasonably quickly.
+const (
+	PositiveTTL = 1 * time.Hour
+	NegativeTTL = 5 * time.Minute
+)
+
+// maxCacheEntries bounds memory use under a churn of distinct domains.
+const maxCacheEntries = 10000
+
+// Resolver is the subset of *net.Resolver the deliverability check needs.
+// It's an interface so tests can stub DNS lookups instead of hitting the
+// network.
+type Resolver interface {
+	LookupMX(ctx context.Context, domain string) ([]*net.MX, error)
+	LookupHost(ctx context.Context, domain string) ([]string, error)
+}
+
+// netResolver adapts *net.Resolver to Resolver.
+type netResolver struct {
+	resolver *net.Resolver
+}
+
+// NewResolver returns a Resolver backed by net.DefaultResolver.
+func NewResolver() Resolver {
+	return &netResolver{resolver: net.DefaultResolver}
+}
+
+func (r *netResolver) LookupMX(ctx context.Context, domain string) ([]*net.MX, error) {
+	return r.resolver.LookupMX(ctx, domain)
+}
+
+func (r *netResolver) LookupHost(ctx context.Context, domain string) ([]string, error) {
+	return r.resolver.LookupHost(ctx, domain)
+}
+
+// DeliverabilityChecker decides whether a domain can plausibly receive mail,
+// by checking for MX records and falling back to A/AAAA records per RFC
+// 5321 section 5.1. Results are cached in-process to keep the signup path
+// fast under bursts.
+type DeliverabilityChecker struct {
+	resolver Resolver
+	timeout  time.Duration
+	cache    *lruCache
+}
+
+// NewDeliverabilityChecker builds a checker that gives each lookup up to
+// timeout to complete.
+func NewDeliverabilityChecker(resolver Resolver, timeout time.Duration) *DeliverabilityChecker {
+	return &DeliverabilityChecker{
+		resolver: resolver,
+		timeout:  timeout,
+		cache:    newLRUCache(maxCacheEntries),
+	}
+}
+
+// IsDeliverable reports whether domain has an MX record, or an A/AAAA
+// record to fall back to. Results are cached per domain for PositiveTTL (or
+// NegativeTTL on a negative result).
+func (c *DeliverabilityChecker) IsDeliverable(ctx context.Context, domain string) (bool, error) {
+	if entry, ok := c.cache.get(domain); ok {
+		return entry.deliverable, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	deliverable, cacheable := c.lookup(ctx, domain)
+	if cacheable {
+		ttl := NegativeTTL
+		if deliverable {
+			ttl = PositiveTTL
+		}
+		c.cache.set(domain, cacheEntry{deliverable: deliverable}, ttl)
+	}
+
+	return deliverable, nil
+}
+
+// lookup reports whether domain can plausibly receive mail, and whether
+// that verdict is safe to cache. A transient resolver error (anything
+// other than a confirmed "no such host") fails open — deliverable, but
+// uncached — so a resolver blip doesn't reject a legitimate signup or
+// poison the cache with a false negative.
+func (c *DeliverabilityChecker) lookup(ctx context.Context, domain string) (deliverable bool, cacheable bool) {
+	mxRecords, err := c.resolver.LookupMX(ctx, domain)
+	if err == nil && len(mxRecords) > 0 {
+		return true, true
+	}
+	if err != nil && !isNotFound(err) {
+		return true, false
+	}
+
+	hosts, err := c.resolver.LookupHost(ctx, domain)
+	if err != nil {
+		if isNotFound(err) {
+			return false, true
+		}
+		return true, false
+	}
+	return len(hosts) > 0, true
+}
+
+func isNotFound(err error) bool {
+	dnsErr, ok := err.(*net.DNSError)
+	return ok && dnsErr.IsNotFound
+}
+
+type cacheEntry struct {
+	deliverable bool
+}
+
+// lruCache is a small fixed-capacity, TTL-aware LRU used to cache resolver
+// results per domain.
+type lruCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+type lruElement struct {
+	key       string
+	entry     cacheEntry
+	expiresAt time.Time
+}
+
+func newLRUCache(capacity int) *lruCache {
+	return &lruCache{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func (c *lruCache) get(key string) (cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return cacheEntry{}, false
+	}
+
+	item := el.Value.(*lruElement)
+	if time.Now().After(item.expiresAt) {
+		c.order.Remove(el)
+		delete(c.items, key)
+		return cacheEntry{}, false
+	}
+
+	c.order.MoveToFront(el)
+	return item.entry, true
+}
+
+func (c *lruCache) set(key string, entry cacheEntry, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		el.Value.(*lruElement).entry = entry
+		el.Value.(*lruElement).expiresAt = time.Now().Add(ttl)
+		c.order.MoveToFront(el)
+		return
+	}
+
+	el := c.order.PushFront(&lruElement{key: key, entry: entry, expiresAt: time.Now().Add(ttl)})
+	c.items[key] = el
+
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		if oldest != nil {
+			c.order.Remove(oldest)
+			delete(c.items, oldest.Value.(*lruElement).key)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package emailcheck
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+//go:embed disposable_domains.txt
+var embeddedDisposableDomains embed.FS
+
+// DisposableList is a set of domains known to be disposable/throwaway email
+// providers.
+type DisposableList struct {
+	domains map[string]struct{}
+}
+
+// NewDisposableList loads the list embedded at build time (sourced from a
+// publicly maintained list; refresh disposable_domains.txt and rebuild to
+// update it) and, if overlayPath is non-empty, merges in one domain per line
+// from that file as well.
+func NewDisposableList(overlayPath string) (*DisposableList, error) {
+	domains := make(map[string]struct{})
+
+	embedded, err := embeddedDisposableDomains.Open("disposable_domains.txt")
+	if err != nil {
+		return nil, fmt.Errorf("emailcheck: open embedded disposable list: %w", err)
+	}
+	defer embedded.Close()
+	if err := addDomainsFromReader(domains, embedded); err != nil {
+		return nil, fmt.Errorf("emailcheck: read embedded disposable list: %w", err)
+	}
+
+	if overlayPath != "" {
+		overlay, err := os.Open(overlayPath)
+		if err != nil {
+			return nil, fmt.Errorf("emailcheck: open %s: %w", overlayPath, err)
+		}
+		defer overlay.Close()
+		if err := addDomainsFromReader(domains, overlay); err != nil {
+			return nil, fmt.Errorf("emailcheck: read %s: %w", overlayPath, err)
+		}
+	}
+
+	return &DisposableList{domains: domains}, nil
+}
+
+func addDomainsFromReader(domains map[string]struct{}, r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		domain := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if domain == "" || strings.HasPrefix(domain, "#") {
+			continue
+		}
+		domains[domain] = struct{}{}
+	}
+	return scanner.Err()
+}
+
+// Contains reports whether domain is a known disposable email provider.
+func (l *DisposableList) Contains(domain string) bool {
+	_, ok := l.domains[strings.ToLower(domain)]
+	return ok
+}
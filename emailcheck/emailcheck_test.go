@@ -0,0 +1,139 @@
+package emailcheck
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stubResolver struct {
+	mx   map[string][]*net.MX
+	host map[string][]string
+	err  map[string]error
+}
+
+func (r *stubResolver) LookupMX(ctx context.Context, domain string) ([]*net.MX, error) {
+	if err, ok := r.err[domain]; ok {
+		return nil, err
+	}
+	return r.mx[domain], nil
+}
+
+func (r *stubResolver) LookupHost(ctx context.Context, domain string) ([]string, error) {
+	if err, ok := r.err[domain]; ok {
+		return nil, err
+	}
+	return r.host[domain], nil
+}
+
+func TestIsDeliverableWithMXRecord(t *testing.T) {
+	resolver := &stubResolver{mx: map[string][]*net.MX{
+		"example.com": {{Host: "mail.example.com", Pref: 10}},
+	}}
+	checker := NewDeliverabilityChecker(resolver, time.Second)
+
+	deliverable, err := checker.IsDeliverable(context.Background(), "example.com")
+	assert.Nil(t, err)
+	assert.True(t, deliverable)
+}
+
+func TestIsDeliverableFallsBackToHostRecord(t *testing.T) {
+	resolver := &stubResolver{
+		mx:   map[string][]*net.MX{},
+		host: map[string][]string{"example.com": {"1.2.3.4"}},
+	}
+	checker := NewDeliverabilityChecker(resolver, time.Second)
+
+	deliverable, err := checker.IsDeliverable(context.Background(), "example.com")
+	assert.Nil(t, err)
+	assert.True(t, deliverable)
+}
+
+func TestIsDeliverableWithNoRecords(t *testing.T) {
+	resolver := &stubResolver{
+		err: map[string]error{"fake.invalid": &net.DNSError{IsNotFound: true}},
+	}
+	checker := NewDeliverabilityChecker(resolver, time.Second)
+
+	deliverable, err := checker.IsDeliverable(context.Background(), "fake.invalid")
+	assert.Nil(t, err)
+	assert.False(t, deliverable)
+}
+
+func TestIsDeliverableFailsOpenOnTransientResolverError(t *testing.T) {
+	boom := errors.New("resolver unavailable")
+	resolver := &stubResolver{err: map[string]error{"example.com": boom}}
+	checker := NewDeliverabilityChecker(resolver, time.Second)
+
+	deliverable, err := checker.IsDeliverable(context.Background(), "example.com")
+	assert.Nil(t, err)
+	assert.True(t, deliverable, "a transient resolver error must not reject a legitimate signup")
+}
+
+func TestIsDeliverableDoesNotCacheTransientResolverError(t *testing.T) {
+	boom := errors.New("resolver unavailable")
+	resolver := &stubResolver{err: map[string]error{"example.com": boom}}
+	checker := NewDeliverabilityChecker(resolver, time.Second)
+
+	_, err := checker.IsDeliverable(context.Background(), "example.com")
+	assert.Nil(t, err)
+
+	// The transient failure must not be cached: once the resolver recovers,
+	// the next lookup should reflect the real (negative) result.
+	resolver.err = map[string]error{"example.com": &net.DNSError{IsNotFound: true}}
+	deliverable, err := checker.IsDeliverable(context.Background(), "example.com")
+	assert.Nil(t, err)
+	assert.False(t, deliverable)
+}
+
+func TestIsDeliverableCachesResult(t *testing.T) {
+	resolver := &stubResolver{mx: map[string][]*net.MX{
+		"example.com": {{Host: "mail.example.com", Pref: 10}},
+	}}
+	checker := NewDeliverabilityChecker(resolver, time.Second)
+
+	deliverable, err := checker.IsDeliverable(context.Background(), "example.com")
+	assert.Nil(t, err)
+	assert.True(t, deliverable)
+
+	// Mutate the resolver after the first call; a cached lookup shouldn't
+	// re-query it.
+	resolver.mx["example.com"] = nil
+	resolver.err = map[string]error{"example.com": errors.New("should not be called")}
+
+	deliverable, err = checker.IsDeliverable(context.Background(), "example.com")
+	assert.Nil(t, err)
+	assert.True(t, deliverable)
+}
+
+func TestDisposableListMatchesEmbeddedDomains(t *testing.T) {
+	list, err := NewDisposableList("")
+	assert.Nil(t, err)
+
+	assert.True(t, list.Contains("mailinator.com"))
+	assert.True(t, list.Contains("MAILINATOR.COM"))
+	assert.False(t, list.Contains("gmail.com"))
+}
+
+func TestDisposableListMergesOverlay(t *testing.T) {
+	dir := t.TempDir()
+	overlayPath := filepath.Join(dir, "overlay.txt")
+	assert.Nil(t, os.WriteFile(overlayPath, []byte("internal-throwaway.test\n"), 0o644))
+
+	list, err := NewDisposableList(overlayPath)
+	assert.Nil(t, err)
+
+	assert.True(t, list.Contains("mailinator.com"))
+	assert.True(t, list.Contains("internal-throwaway.test"))
+}
+
+func TestNewDisposableListErrorsOnMissingOverlay(t *testing.T) {
+	_, err := NewDisposableList("/does/not/exist.txt")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,48 @@
+// Package httperr defines the API's structured error envelope and the
+// fixed set of machine-readable error codes handlers can return.
+package httperr
+
+import "github.com/gin-gonic/gin"
+
+// Code is a stable, machine-readable error code. Clients should branch on
+// Code, never on Message, which is free-form and may change.
+type Code string
+
+// The full set of error codes the API can return. Any 4xx/5xx response
+// uses one of these.
+const (
+	CodeInvalidRequest      Code = "invalid-request"
+	CodeInvalidEmail        Code = "invalid-email"
+	CodeEmailTooLong        Code = "email-too-long"
+	CodeEmailAlreadyInUse   Code = "email-already-in-use"
+	CodeEmailUndeliverable  Code = "email-undeliverable"
+	CodeEmailDisposable     Code = "email-disposable"
+	CodeRateLimited         Code = "rate-limited"
+	CodeUnauthorized        Code = "unauthorized"
+	CodeInternalServerError Code = "internal-server-error"
+)
+
+// Body is the JSON shape of the "error" field in every error response.
+type Body struct {
+	Code    Code   `json:"code"`
+	Message string `json:"message"`
+	Field   string `json:"field,omitempty"`
+}
+
+// Envelope is the top-level JSON shape of every error response.
+type Envelope struct {
+	Error Body `json:"error"`
+}
+
+// Respond writes a structured error envelope with the given status, code,
+// and message. field may be empty when the error isn't tied to one input.
+func Respond(c *gin.Context, status int, code Code, message, field string) {
+	c.JSON(status, Envelope{Error: Body{Code: code, Message: message, Field: field}})
+}
+
+// Abort writes a structured error envelope and aborts the request, stopping
+// any remaining middleware and handlers from running. Use this from
+// middleware; use Respond from terminal handlers.
+func Abort(c *gin.Context, status int, code Code, message, field string) {
+	c.AbortWithStatusJSON(status, Envelope{Error: Body{Code: code, Message: message, Field: field}})
+}
@@ -0,0 +1,47 @@
+package httperr
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRespondWritesStructuredEnvelope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	Respond(c, 409, CodeEmailAlreadyInUse, "email is already in use", "email")
+
+	if w.Code != 409 {
+		t.Fatalf("expected status 409, got %d", w.Code)
+	}
+
+	var envelope Envelope
+	if err := json.Unmarshal(w.Body.Bytes(), &envelope); err != nil {
+		t.Fatalf("failed to unmarshal body: %v", err)
+	}
+	if envelope.Error.Code != CodeEmailAlreadyInUse {
+		t.Fatalf("expected code %q, got %q", CodeEmailAlreadyInUse, envelope.Error.Code)
+	}
+	if envelope.Error.Field != "email" {
+		t.Fatalf("expected field %q, got %q", "email", envelope.Error.Field)
+	}
+}
+
+func TestAbortStopsSubsequentHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	Abort(c, 401, CodeUnauthorized, "unauthorized", "")
+
+	if !c.IsAborted() {
+		t.Fatal("expected context to be aborted")
+	}
+	if w.Code != 401 {
+		t.Fatalf("expected status 401, got %d", w.Code)
+	}
+}
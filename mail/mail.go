@@ -0,0 +1,69 @@
+// Package mail sends transactional emails for the waitlist, such as the
+// double opt-in verification message, over SMTP.
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"net/smtp"
+	"text/template"
+)
+
+//go:embed templates/*.tmpl
+var templatesFS embed.FS
+
+// Mailer sends the emails the signup flow needs. It is an interface so
+// tests can swap in a fake implementation instead of talking to a real
+// SMTP server.
+type Mailer interface {
+	SendVerificationEmail(to, link string) error
+}
+
+// SMTPMailer sends mail through an SMTP relay using net/smtp.
+type SMTPMailer struct {
+	Host string
+	Port string
+	User string
+	Pass string
+	From string
+
+	templates *template.Template
+}
+
+// NewSMTPMailer builds an SMTPMailer and parses the embedded templates.
+func NewSMTPMailer(host, port, user, pass, from string) (*SMTPMailer, error) {
+	templates, err := template.ParseFS(templatesFS, "templates/*.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("mail: parse templates: %w", err)
+	}
+	return &SMTPMailer{
+		Host:      host,
+		Port:      port,
+		User:      user,
+		Pass:      pass,
+		From:      from,
+		templates: templates,
+	}, nil
+}
+
+type verificationData struct {
+	Link string
+}
+
+// SendVerificationEmail sends the double opt-in confirmation email containing link.
+func (m *SMTPMailer) SendVerificationEmail(to, link string) error {
+	var body bytes.Buffer
+	if err := m.templates.ExecuteTemplate(&body, "verification.tmpl", verificationData{Link: link}); err != nil {
+		return fmt.Errorf("mail: render verification template: %w", err)
+	}
+
+	msg := fmt.Sprintf(
+		"From: %s\r\nTo: %s\r\nSubject: Confirm your email\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=\"UTF-8\"\r\n\r\n%s",
+		m.From, to, body.String(),
+	)
+
+	addr := fmt.Sprintf("%s:%s", m.Host, m.Port)
+	auth := smtp.PlainAuth("", m.User, m.Pass, m.Host)
+	return smtp.SendMail(addr, auth, m.From, []string{to}, []byte(msg))
+}
@@ -0,0 +1,13 @@
+package mail
+
+import "testing"
+
+func TestNewSMTPMailerParsesTemplates(t *testing.T) {
+	m, err := NewSMTPMailer("localhost", "1025", "user", "pass", "noreply@dorado.dev")
+	if err != nil {
+		t.Fatalf("NewSMTPMailer returned error: %v", err)
+	}
+	if m.templates.Lookup("verification.tmpl") == nil {
+		t.Fatal("verification.tmpl not found in parsed templates")
+	}
+}
@@ -1,50 +1,86 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
 	"log"
-	"net/mail"
+	stdmail "net/mail"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/getkin/kin-openapi/routers"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	limiter "github.com/ulule/limiter/v3"
 	mgin "github.com/ulule/limiter/v3/drivers/middleware/gin"
-	"github.com/ulule/limiter/v3/drivers/store/memory"
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+
+	"github.com/machidevdev/dorado-be/auth"
+	"github.com/machidevdev/dorado-be/emailcheck"
+	"github.com/machidevdev/dorado-be/httperr"
+	"github.com/machidevdev/dorado-be/mail"
+	"github.com/machidevdev/dorado-be/openapi"
+	"github.com/machidevdev/dorado-be/ratelimit"
+)
+
+// defaultMXLookupTimeout bounds how long the deliverability check waits on
+// DNS before giving up, so the signup path can't hang on a slow resolver.
+const defaultMXLookupTimeout = 3 * time.Second
+
+// verificationTokenTTL is how long a signup's verification link stays valid.
+const verificationTokenTTL = 24 * time.Hour
+
+var errVerificationTokenExpired = errors.New("verification token expired")
+
+// RoleUser and RoleAdmin are the values the User.Role column can hold.
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
 )
 
 type User struct {
-	ID        uint
-	Email     string `gorm:"unique"`
-	CreatedAt time.Time
+	ID                    uint
+	Email                 string     `gorm:"unique"`
+	Verified              bool
+	VerifiedAt            *time.Time
+	VerificationToken     string     `gorm:"index" json:"-"`
+	VerificationExpiresAt *time.Time `json:"-"`
+	PasswordHash          string     `json:"-"`
+	Role                  string     `gorm:"default:user"`
+	CreatedAt             time.Time
 }
 
 type UserPost struct {
 	Email string `json:"email"`
 }
 
-// validateEmail performs comprehensive email validation
-func validateEmail(email string) (string, error) {
+// normalizeEmailSyntax performs purely syntactic email validation and
+// normalization: no network calls, safe to run on every request (including
+// for rate-limiter keying).
+func normalizeEmailSyntax(email string) (string, error) {
 	// Trim whitespace
 	email = strings.TrimSpace(email)
 
 	// Check if empty
 	if email == "" {
-		return "", &ValidationError{Field: "email", Message: "email cannot be empty"}
+		return "", &ValidationError{Field: "email", Message: "email cannot be empty", Code: httperr.CodeInvalidRequest}
 	}
 
 	// Check length constraints
 	if len(email) > 254 {
-		return "", &ValidationError{Field: "email", Message: "email is too long (max 254 characters)"}
+		return "", &ValidationError{Field: "email", Message: "email is too long (max 254 characters)", Code: httperr.CodeEmailTooLong}
 	}
 
 	// Parse email using Go's standard library
-	addr, err := mail.ParseAddress(email)
+	addr, err := stdmail.ParseAddress(email)
 	if err != nil {
-		return "", &ValidationError{Field: "email", Message: "invalid email format"}
+		return "", &ValidationError{Field: "email", Message: "invalid email format", Code: httperr.CodeInvalidEmail}
 	}
 
 	// Extract the email part (in case name was provided like "John Doe <john@example.com>")
@@ -53,7 +89,7 @@ func validateEmail(email string) (string, error) {
 	// Split email into local and domain parts
 	parts := strings.Split(email, "@")
 	if len(parts) != 2 {
-		return "", &ValidationError{Field: "email", Message: "invalid email format"}
+		return "", &ValidationError{Field: "email", Message: "invalid email format", Code: httperr.CodeInvalidEmail}
 	}
 
 	localPart := parts[0]
@@ -61,17 +97,17 @@ func validateEmail(email string) (string, error) {
 
 	// Validate local part
 	if len(localPart) == 0 || len(localPart) > 64 {
-		return "", &ValidationError{Field: "email", Message: "email local part is invalid"}
+		return "", &ValidationError{Field: "email", Message: "email local part is invalid", Code: httperr.CodeInvalidEmail}
 	}
 
 	// Validate domain part
 	if len(domain) == 0 || len(domain) > 255 {
-		return "", &ValidationError{Field: "email", Message: "email domain is invalid"}
+		return "", &ValidationError{Field: "email", Message: "email domain is invalid", Code: httperr.CodeInvalidEmail}
 	}
 
 	// Check for at least one dot in domain
 	if !strings.Contains(domain, ".") {
-		return "", &ValidationError{Field: "email", Message: "email domain must contain at least one dot"}
+		return "", &ValidationError{Field: "email", Message: "email domain must contain at least one dot", Code: httperr.CodeInvalidEmail}
 	}
 
 	// Convert to lowercase for consistency
@@ -80,32 +116,178 @@ func validateEmail(email string) (string, error) {
 	return email, nil
 }
 
-// ValidationError represents a validation error
+// emailValidator validates a signup email beyond syntax: optionally
+// rejecting disposable-provider domains and domains with no mail exchanger.
+// Both checks are individually toggleable so tests (and operators without
+// network egress) can disable them.
+type emailValidator struct {
+	checkDisposable bool
+	checkMX         bool
+	disposable      *emailcheck.DisposableList
+	deliverability  *emailcheck.DeliverabilityChecker
+}
+
+// newEmailValidator builds an emailValidator. disposable and deliverability
+// may be nil when their corresponding check is disabled.
+func newEmailValidator(checkDisposable, checkMX bool, disposable *emailcheck.DisposableList, deliverability *emailcheck.DeliverabilityChecker) *emailValidator {
+	return &emailValidator{
+		checkDisposable: checkDisposable,
+		checkMX:         checkMX,
+		disposable:      disposable,
+		deliverability:  deliverability,
+	}
+}
+
+// Validate normalizes email and, depending on configuration, rejects
+// disposable-provider domains and domains with no mail exchanger. ctx bounds
+// the DNS lookup the MX check performs.
+func (v *emailValidator) Validate(ctx context.Context, email string) (string, error) {
+	normalized, err := normalizeEmailSyntax(email)
+	if err != nil {
+		return "", err
+	}
+
+	domain := normalized[strings.LastIndex(normalized, "@")+1:]
+
+	if v.checkDisposable && v.disposable.Contains(domain) {
+		return "", &ValidationError{Field: "email", Message: "disposable email addresses are not allowed", Code: httperr.CodeEmailDisposable}
+	}
+
+	if v.checkMX {
+		deliverable, err := v.deliverability.IsDeliverable(ctx, domain)
+		if err != nil || !deliverable {
+			return "", &ValidationError{Field: "email", Message: "email domain cannot receive mail", Code: httperr.CodeEmailUndeliverable}
+		}
+	}
+
+	return normalized, nil
+}
+
+// ValidationError represents a validation error, carrying the structured
+// error code the handler should respond with.
 type ValidationError struct {
 	Field   string
 	Message string
+	Code    httperr.Code
 }
 
 func (e *ValidationError) Error() string {
 	return e.Message
 }
 
-func main() {
-	db_url := os.Getenv("DATABASE_URL")
-	if db_url == "" {
-		log.Println("ERROR: DATABASE_URL environment variable is not set")
+// respondValidationError writes the structured error response for err,
+// using its ValidationError code when available and falling back to
+// invalid-request otherwise.
+func respondValidationError(c *gin.Context, err error) {
+	if ve, ok := err.(*ValidationError); ok {
+		httperr.Respond(c, 400, ve.Code, ve.Message, ve.Field)
+		return
+	}
+	httperr.Respond(c, 400, httperr.CodeInvalidRequest, err.Error(), "")
+}
+
+// isDuplicateKeyError reports whether err represents a unique-constraint
+// violation, across both GORM's generic sentinel and the driver-specific
+// messages SQLite and Postgres return.
+func isDuplicateKeyError(err error) bool {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "UNIQUE constraint failed") ||
+		strings.Contains(msg, "duplicate key value violates unique constraint")
+}
+
+// generateVerificationToken returns a random 32-byte URL-safe token used for
+// the double opt-in email verification link.
+func generateVerificationToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func envOrDefault(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// envBoolOrDefault parses key as a bool, returning def if it's unset or
+// unparseable.
+func envBoolOrDefault(key string, def bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	parsed, err := strconv.ParseBool(v)
+	if err != nil {
+		return def
+	}
+	return parsed
+}
+
+// seedAdmin creates the first admin user from ADMIN_EMAIL/ADMIN_PASSWORD and
+// exits. It's invoked via `go run . seed-admin`.
+func seedAdmin(db *gorm.DB, validator *emailValidator) {
+	email := os.Getenv("ADMIN_EMAIL")
+	password := os.Getenv("ADMIN_PASSWORD")
+	if email == "" || password == "" {
+		log.Println("ERROR: ADMIN_EMAIL and ADMIN_PASSWORD must be set")
 		os.Exit(1)
 	}
 
-	db, err := gorm.Open(postgres.Open(db_url), &gorm.Config{})
+	validatedEmail, err := validator.Validate(context.Background(), email)
 	if err != nil {
-		log.Printf("ERROR: Failed to connect to database: %v\n", err)
+		log.Printf("ERROR: invalid ADMIN_EMAIL: %v\n", err)
 		os.Exit(1)
 	}
 
-	// Auto-migrate database schema
-	db.AutoMigrate(&User{})
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		log.Printf("ERROR: failed to hash admin password: %v\n", err)
+		os.Exit(1)
+	}
+
+	now := time.Now()
+	admin := User{
+		Email:        validatedEmail,
+		PasswordHash: string(hash),
+		Role:         RoleAdmin,
+		Verified:     true,
+		VerifiedAt:   &now,
+	}
+	if err := db.Create(&admin).Error; err != nil {
+		log.Printf("ERROR: failed to create admin user: %v\n", err)
+		os.Exit(1)
+	}
+
+	log.Printf("Admin user created: %s\n", admin.Email)
+	os.Exit(0)
+}
 
+// routerDeps bundles everything newRouter needs to wire up the app's
+// middleware and routes. main() builds it from real infrastructure
+// (Postgres, SMTP, Redis); the test suite builds it from fakes, so both
+// exercise the exact same route registration and can't drift apart.
+type routerDeps struct {
+	db                     *gorm.DB
+	authManager            *auth.Manager
+	mailer                 mail.Mailer
+	validator              *emailValidator
+	rateStore              limiter.Store
+	openapiRouter          routers.Router
+	baseURL                string
+	verificationSuccessURL string
+}
+
+// newRouter builds the app's Gin engine: the middleware chain, then every
+// route. This is the single source of truth for route registration; main()
+// and the test suite both call it so they can never diverge from each
+// other.
+func newRouter(deps routerDeps) *gin.Engine {
 	r := gin.Default()
 
 	// CORS configuration - only allow requests from Vercel domain
@@ -118,85 +300,355 @@ func main() {
 		MaxAge:           12 * time.Hour,
 	}))
 
-	// Rate limiting configuration
-	// 10 requests per minute per IP for global rate limiting
-	rate := limiter.Rate{
-		Period: 1 * time.Minute,
-		Limit:  10,
-	}
-	store := memory.NewStore()
-	rateLimitMiddleware := mgin.NewMiddleware(limiter.New(store, rate))
+	// Validate every request against the OpenAPI spec before it reaches a
+	// handler; optionally validate responses too (test/CI use, see
+	// openapi.ValidateResponses).
+	r.Use(openapi.ValidateRequests(deps.openapiRouter))
+	r.Use(openapi.ValidateResponses(deps.openapiRouter))
+
+	rateLimitMiddleware := mgin.NewMiddleware(limiter.New(deps.rateStore, ratelimit.GlobalIPRate),
+		mgin.WithLimitReachedHandler(func(c *gin.Context) {
+			httperr.Respond(c, 429, httperr.CodeRateLimited, "too many requests, please try again later", "")
+		}))
+	emailRateLimitMiddleware := ratelimit.PerEmail(deps.rateStore, ratelimit.PerEmailRate, normalizeEmailSyntax)
 
 	r.GET("/ping", func(c *gin.Context) {
 		c.JSON(200, gin.H{
 			"message": "pong",
 		})
 	})
-	r.GET("/users", func(c *gin.Context) {
-		// Password protection for admin access
-		const adminPassword = "Dorado2025!?"
-		authHeader := c.GetHeader("Authorization")
-
-		if authHeader == "" {
-			c.JSON(401, gin.H{
-				"error": "unauthorized: missing authorization header",
-			})
+	r.GET("/openapi.yaml", openapi.ServeSpec)
+	r.GET("/docs", openapi.ServeDocs)
+	r.GET("/users", auth.RequireRole(deps.authManager, RoleAdmin), func(c *gin.Context) {
+		query := deps.db
+		if v := c.Query("verified"); v != "" {
+			verified, err := strconv.ParseBool(v)
+			if err != nil {
+				httperr.Respond(c, 400, httperr.CodeInvalidRequest, "invalid verified filter", "verified")
+				return
+			}
+			query = query.Where("verified = ?", verified)
+		}
+
+		var users []User
+		if err := query.Find(&users).Error; err != nil {
+			log.Printf("ERROR: failed to list users: %v\n", err)
+			httperr.Respond(c, 500, httperr.CodeInternalServerError, "internal server error", "")
+			return
+		}
+		c.JSON(200, users)
+	})
+
+	r.POST("/admin/login", func(c *gin.Context) {
+		var body struct {
+			Email    string `json:"email"`
+			Password string `json:"password"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			httperr.Respond(c, 400, httperr.CodeInvalidRequest, err.Error(), "")
+			return
+		}
+
+		var u User
+		if err := deps.db.Where("email = ? AND role = ?", strings.ToLower(strings.TrimSpace(body.Email)), RoleAdmin).First(&u).Error; err != nil {
+			httperr.Respond(c, 401, httperr.CodeUnauthorized, "invalid email or password", "")
 			return
 		}
 
-		// Extract password from "Bearer <password>" format
-		password := strings.TrimPrefix(authHeader, "Bearer ")
-		if password == authHeader || password != adminPassword {
-			c.JSON(401, gin.H{
-				"error": "unauthorized: invalid password",
+		if err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(body.Password)); err != nil {
+			httperr.Respond(c, 401, httperr.CodeUnauthorized, "invalid email or password", "")
+			return
+		}
+
+		accessToken, err := deps.authManager.IssueAccessToken(u.ID, u.Email, u.Role)
+		if err != nil {
+			log.Printf("ERROR: failed to issue access token: %v\n", err)
+			httperr.Respond(c, 500, httperr.CodeInternalServerError, "internal server error", "")
+			return
+		}
+		refreshToken, err := deps.authManager.IssueRefreshToken(u.ID, u.Email, u.Role)
+		if err != nil {
+			log.Printf("ERROR: failed to issue refresh token: %v\n", err)
+			httperr.Respond(c, 500, httperr.CodeInternalServerError, "internal server error", "")
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"access_token":  accessToken,
+			"refresh_token": refreshToken,
+		})
+	})
+
+	r.POST("/admin/refresh", func(c *gin.Context) {
+		var body struct {
+			RefreshToken string `json:"refresh_token"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			httperr.Respond(c, 400, httperr.CodeInvalidRequest, err.Error(), "")
+			return
+		}
+
+		claims, err := deps.authManager.Verify(body.RefreshToken, auth.TokenTypeRefresh)
+		if err != nil {
+			httperr.Respond(c, 401, httperr.CodeUnauthorized, "invalid or expired refresh token", "")
+			return
+		}
+
+		accessToken, err := deps.authManager.IssueAccessToken(claims.UserID, claims.Email, claims.Role)
+		if err != nil {
+			log.Printf("ERROR: failed to issue access token: %v\n", err)
+			httperr.Respond(c, 500, httperr.CodeInternalServerError, "internal server error", "")
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"access_token": accessToken,
+		})
+	})
+
+	r.GET("/users/verify", func(c *gin.Context) {
+		token := c.Query("token")
+		if token == "" {
+			httperr.Respond(c, 400, httperr.CodeInvalidRequest, "missing token", "token")
+			return
+		}
+
+		err := deps.db.Transaction(func(tx *gorm.DB) error {
+			var u User
+			if err := tx.Where("verification_token = ?", token).First(&u).Error; err != nil {
+				return err
+			}
+			if u.VerificationExpiresAt == nil || time.Now().After(*u.VerificationExpiresAt) {
+				return errVerificationTokenExpired
+			}
+
+			now := time.Now()
+			return tx.Model(&u).Updates(map[string]interface{}{
+				"verified":                true,
+				"verified_at":             now,
+				"verification_token":      "",
+				"verification_expires_at": nil,
+			}).Error
+		})
+
+		if err != nil {
+			if errors.Is(err, errVerificationTokenExpired) {
+				httperr.Respond(c, 400, httperr.CodeInvalidRequest, "verification token expired", "token")
+				return
+			}
+			httperr.Respond(c, 400, httperr.CodeInvalidRequest, "invalid verification token", "token")
+			return
+		}
+
+		if deps.verificationSuccessURL != "" {
+			c.Redirect(302, deps.verificationSuccessURL)
+			return
+		}
+		c.JSON(200, gin.H{
+			"message": "email verified",
+		})
+	})
+
+	r.POST("/users/resend-verification", emailRateLimitMiddleware, func(c *gin.Context) {
+		var body UserPost
+		if err := c.ShouldBindJSON(&body); err != nil {
+			httperr.Respond(c, 400, httperr.CodeInvalidRequest, err.Error(), "")
+			return
+		}
+
+		validatedEmail, err := deps.validator.Validate(c.Request.Context(), body.Email)
+		if err != nil {
+			respondValidationError(c, err)
+			return
+		}
+
+		var u User
+		if err := deps.db.Where("email = ?", validatedEmail).First(&u).Error; err != nil {
+			// Don't reveal whether the address is registered.
+			c.JSON(200, gin.H{
+				"message": "if the address is registered, a verification email has been sent",
 			})
 			return
 		}
 
-		var users []User
-		if err := db.Find(&users).Error; err != nil {
-			c.JSON(500, gin.H{
-				"error": err.Error(),
+		if u.Verified {
+			// Don't reveal whether the address is registered or already
+			// verified; same generic response as the not-found case above.
+			c.JSON(200, gin.H{
+				"message": "if the address is registered, a verification email has been sent",
 			})
 			return
 		}
-		c.JSON(200, users)
+
+		token, err := generateVerificationToken()
+		if err != nil {
+			log.Printf("ERROR: failed to generate verification token: %v\n", err)
+			httperr.Respond(c, 500, httperr.CodeInternalServerError, "internal server error", "")
+			return
+		}
+		expiresAt := time.Now().Add(verificationTokenTTL)
+		if err := deps.db.Model(&u).Updates(map[string]interface{}{
+			"verification_token":      token,
+			"verification_expires_at": expiresAt,
+		}).Error; err != nil {
+			log.Printf("ERROR: failed to save verification token: %v\n", err)
+			httperr.Respond(c, 500, httperr.CodeInternalServerError, "internal server error", "")
+			return
+		}
+
+		link := deps.baseURL + "/users/verify?token=" + token
+		if err := deps.mailer.SendVerificationEmail(u.Email, link); err != nil {
+			log.Printf("ERROR: failed to send verification email to %s: %v\n", u.Email, err)
+		}
+
+		c.JSON(200, gin.H{
+			"message": "if the address is registered, a verification email has been sent",
+		})
 	})
 
 	// Apply rate limiting to POST /users endpoint
-	r.POST("/users", rateLimitMiddleware, func(c *gin.Context) {
+	r.POST("/users", rateLimitMiddleware, emailRateLimitMiddleware, func(c *gin.Context) {
 		// read user from request body
 		var user UserPost
 		if err := c.ShouldBindJSON(&user); err != nil {
-			c.JSON(400, gin.H{
-				"error": err.Error(),
-			})
+			httperr.Respond(c, 400, httperr.CodeInvalidRequest, err.Error(), "")
 			return
 		}
 
 		// validate and normalize email
-		validatedEmail, err := validateEmail(user.Email)
+		validatedEmail, err := deps.validator.Validate(c.Request.Context(), user.Email)
 		if err != nil {
-			c.JSON(400, gin.H{
-				"error": err.Error(),
-			})
+			respondValidationError(c, err)
+			return
+		}
+
+		token, err := generateVerificationToken()
+		if err != nil {
+			log.Printf("ERROR: failed to generate verification token: %v\n", err)
+			httperr.Respond(c, 500, httperr.CodeInternalServerError, "internal server error", "")
 			return
 		}
+		expiresAt := time.Now().Add(verificationTokenTTL)
 
 		// create user with validated email
-		result := db.Create(&User{Email: validatedEmail})
+		newUser := User{
+			Email:                 validatedEmail,
+			VerificationToken:     token,
+			VerificationExpiresAt: &expiresAt,
+		}
+		result := deps.db.Create(&newUser)
 		if result.Error != nil {
-			c.JSON(500, gin.H{
-				"error": result.Error.Error(),
-			})
+			if isDuplicateKeyError(result.Error) {
+				httperr.Respond(c, 409, httperr.CodeEmailAlreadyInUse, "email is already in use", "email")
+				return
+			}
+			log.Printf("ERROR: failed to create user: %v\n", result.Error)
+			httperr.Respond(c, 500, httperr.CodeInternalServerError, "internal server error", "")
 			return
 		}
 
+		link := deps.baseURL + "/users/verify?token=" + token
+		if err := deps.mailer.SendVerificationEmail(newUser.Email, link); err != nil {
+			log.Printf("ERROR: failed to send verification email to %s: %v\n", newUser.Email, err)
+		}
+
 		c.JSON(200, gin.H{
 			"message": "user created",
 		})
 	})
 
+	return r
+}
+
+func main() {
+	db_url := os.Getenv("DATABASE_URL")
+	if db_url == "" {
+		log.Println("ERROR: DATABASE_URL environment variable is not set")
+		os.Exit(1)
+	}
+
+	db, err := gorm.Open(postgres.Open(db_url), &gorm.Config{})
+	if err != nil {
+		log.Printf("ERROR: Failed to connect to database: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Auto-migrate database schema
+	db.AutoMigrate(&User{})
+
+	checkDisposable := envBoolOrDefault("EMAIL_CHECK_DISPOSABLE", true)
+	checkMX := envBoolOrDefault("EMAIL_CHECK_MX", true)
+
+	var disposableList *emailcheck.DisposableList
+	if checkDisposable {
+		disposableList, err = emailcheck.NewDisposableList(os.Getenv("DISPOSABLE_DOMAINS_PATH"))
+		if err != nil {
+			log.Printf("ERROR: Failed to load disposable domain list: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	var deliverabilityChecker *emailcheck.DeliverabilityChecker
+	if checkMX {
+		deliverabilityChecker = emailcheck.NewDeliverabilityChecker(emailcheck.NewResolver(), defaultMXLookupTimeout)
+	}
+
+	validator := newEmailValidator(checkDisposable, checkMX, disposableList, deliverabilityChecker)
+
+	if len(os.Args) > 1 && os.Args[1] == "seed-admin" {
+		seedAdmin(db, validator)
+	}
+
+	authManager, err := auth.NewManager(
+		envOrDefault("JWT_PRIVATE_KEY_PATH", "jwt_private.pem"),
+		envOrDefault("JWT_PUBLIC_KEY_PATH", "jwt_public.pem"),
+	)
+	if err != nil {
+		log.Printf("ERROR: Failed to initialize auth manager: %v\n", err)
+		os.Exit(1)
+	}
+
+	mailer, err := mail.NewSMTPMailer(
+		os.Getenv("SMTP_HOST"),
+		os.Getenv("SMTP_PORT"),
+		os.Getenv("SMTP_USER"),
+		os.Getenv("SMTP_PASS"),
+		os.Getenv("MAIL_FROM"),
+	)
+	if err != nil {
+		log.Printf("ERROR: Failed to initialize mailer: %v\n", err)
+		os.Exit(1)
+	}
+
+	baseURL := os.Getenv("BASE_URL")
+	verificationSuccessURL := os.Getenv("VERIFICATION_SUCCESS_URL")
+
+	_, openapiRouter, err := openapi.Load()
+	if err != nil {
+		log.Printf("ERROR: Failed to load OpenAPI spec: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Rate limiting configuration. Backed by Redis when REDIS_URL is set, so
+	// limits are shared across replicas and survive deploys; falls back to
+	// an in-memory store for local dev.
+	rateStore, err := ratelimit.NewStore(os.Getenv("REDIS_URL"))
+	if err != nil {
+		log.Printf("ERROR: Failed to initialize rate limit store: %v\n", err)
+		os.Exit(1)
+	}
+
+	r := newRouter(routerDeps{
+		db:                     db,
+		authManager:            authManager,
+		mailer:                 mailer,
+		validator:              validator,
+		rateStore:              rateStore,
+		openapiRouter:          openapiRouter,
+		baseURL:                baseURL,
+		verificationSuccessURL: verificationSuccessURL,
+	})
+
 	log.Println("Database connected successfully, starting server...")
 	r.Run() // listen and serve on 0.0.0.0:8080 (for windows "localhost:8080")
 }
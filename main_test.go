@@ -2,18 +2,51 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/ulule/limiter/v3/drivers/store/memory"
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+
+	"github.com/machidevdev/dorado-be/auth"
+	"github.com/machidevdev/dorado-be/emailcheck"
+	"github.com/machidevdev/dorado-be/httperr"
+	"github.com/machidevdev/dorado-be/mail"
+	"github.com/machidevdev/dorado-be/openapi"
 )
 
+// testEmailValidator has both network-dependent checks disabled so tests
+// stay fast and offline; TestValidator* below exercise those checks
+// directly (the MX check against a stubbed resolver).
+var testEmailValidator = newEmailValidator(false, false, nil, nil)
+
+// testAuthManager is shared across tests; its keypair is generated once in a
+// temp dir so issued and verified tokens stay consistent within a run.
+var testAuthManager = func() *auth.Manager {
+	dir, err := os.MkdirTemp("", "dorado-test-keys-*")
+	if err != nil {
+		panic(err)
+	}
+	m, err := auth.NewManager(filepath.Join(dir, "priv.pem"), filepath.Join(dir, "pub.pem"))
+	if err != nil {
+		panic(err)
+	}
+	return m
+}()
+
 func setupTestDB() *gorm.DB {
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 	if err != nil {
@@ -23,42 +56,62 @@ func setupTestDB() *gorm.DB {
 	return db
 }
 
+// errorBody unmarshals the "error" field of a structured httperr.Envelope response.
+func errorBody(t *testing.T, w *httptest.ResponseRecorder) httperr.Body {
+	t.Helper()
+	var envelope httperr.Envelope
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &envelope))
+	return envelope.Error
+}
+
+// fakeMailer records sent verification emails instead of talking to SMTP.
+type fakeMailer struct {
+	mu   sync.Mutex
+	sent map[string]string // email -> link
+}
+
+func newFakeMailer() *fakeMailer {
+	return &fakeMailer{sent: make(map[string]string)}
+}
+
+func (m *fakeMailer) SendVerificationEmail(to, link string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent[to] = link
+	return nil
+}
+
+func (m *fakeMailer) linkFor(to string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.sent[to]
+}
+
 func setupRouter(db *gorm.DB) *gin.Engine {
+	return setupRouterWithMailer(db, newFakeMailer())
+}
+
+// setupRouterWithMailer builds the router exactly as main() does, via
+// newRouter, so the test suite exercises the real middleware chain
+// (including OpenAPI validation and both rate limiters) and can't silently
+// drift from production behavior.
+func setupRouterWithMailer(db *gorm.DB, mailer mail.Mailer) *gin.Engine {
 	gin.SetMode(gin.TestMode)
-	r := gin.Default()
-
-	r.POST("/users", func(c *gin.Context) {
-		var user UserPost
-		if err := c.ShouldBindJSON(&user); err != nil {
-			c.JSON(400, gin.H{
-				"error": err.Error(),
-			})
-			return
-		}
-
-		// validate and normalize email
-		validatedEmail, err := validateEmail(user.Email)
-		if err != nil {
-			c.JSON(400, gin.H{
-				"error": err.Error(),
-			})
-			return
-		}
-
-		result := db.Create(&User{Email: validatedEmail})
-		if result.Error != nil {
-			c.JSON(500, gin.H{
-				"error": result.Error.Error(),
-			})
-			return
-		}
-
-		c.JSON(200, gin.H{
-			"message": "user created",
-		})
-	})
 
-	return r
+	_, openapiRouter, err := openapi.Load()
+	if err != nil {
+		panic(err)
+	}
+
+	return newRouter(routerDeps{
+		db:            db,
+		authManager:   testAuthManager,
+		mailer:        mailer,
+		validator:     testEmailValidator,
+		rateStore:     memory.NewStore(),
+		openapiRouter: openapiRouter,
+		baseURL:       "http://localhost:8080",
+	})
 }
 
 func TestPostUserWithValidEmail(t *testing.T) {
@@ -108,12 +161,12 @@ func TestPostUserWithDuplicateEmail(t *testing.T) {
 
 	router.ServeHTTP(w, req)
 
-	assert.Equal(t, 500, w.Code)
+	assert.Equal(t, 409, w.Code)
 
-	var response map[string]string
-	err := json.Unmarshal(w.Body.Bytes(), &response)
-	assert.Nil(t, err)
-	assert.Contains(t, response["error"], "UNIQUE")
+	body := errorBody(t, w)
+	assert.Equal(t, httperr.CodeEmailAlreadyInUse, body.Code)
+	assert.Equal(t, "email", body.Field)
+	assert.NotContains(t, body.Message, "UNIQUE")
 }
 
 func TestPostUserWithInvalidJSON(t *testing.T) {
@@ -130,10 +183,9 @@ func TestPostUserWithInvalidJSON(t *testing.T) {
 
 	assert.Equal(t, 400, w.Code)
 
-	var response map[string]string
-	err := json.Unmarshal(w.Body.Bytes(), &response)
-	assert.Nil(t, err)
-	assert.NotEmpty(t, response["error"])
+	body := errorBody(t, w)
+	assert.Equal(t, httperr.CodeInvalidRequest, body.Code)
+	assert.NotEmpty(t, body.Message)
 }
 
 func TestPostUserWithEmptyEmail(t *testing.T) {
@@ -153,10 +205,9 @@ func TestPostUserWithEmptyEmail(t *testing.T) {
 
 	assert.Equal(t, 400, w.Code)
 
-	var response map[string]string
-	err := json.Unmarshal(w.Body.Bytes(), &response)
-	assert.Nil(t, err)
-	assert.Equal(t, "email cannot be empty", response["error"])
+	body := errorBody(t, w)
+	assert.Equal(t, httperr.CodeInvalidRequest, body.Code)
+	assert.Equal(t, "email cannot be empty", body.Message)
 }
 
 func TestPostUserWithMissingEmailField(t *testing.T) {
@@ -173,10 +224,12 @@ func TestPostUserWithMissingEmailField(t *testing.T) {
 
 	assert.Equal(t, 400, w.Code)
 
-	var response map[string]string
-	err := json.Unmarshal(w.Body.Bytes(), &response)
-	assert.Nil(t, err)
-	assert.Equal(t, "email cannot be empty", response["error"])
+	// UserPost requires "email" in the spec, so a body omitting it entirely
+	// never reaches the handler's own "email cannot be empty" check — it's
+	// rejected by openapi.ValidateRequests first.
+	body := errorBody(t, w)
+	assert.Equal(t, httperr.CodeInvalidRequest, body.Code)
+	assert.Equal(t, "request does not match the documented API contract", body.Message)
 }
 
 func TestPostUserWithWhitespaceEmail(t *testing.T) {
@@ -196,10 +249,8 @@ func TestPostUserWithWhitespaceEmail(t *testing.T) {
 
 	assert.Equal(t, 400, w.Code)
 
-	var response map[string]string
-	err := json.Unmarshal(w.Body.Bytes(), &response)
-	assert.Nil(t, err)
-	assert.Equal(t, "email cannot be empty", response["error"])
+	body := errorBody(t, w)
+	assert.Equal(t, "email cannot be empty", body.Message)
 }
 
 func TestPostUserWithInvalidEmailFormat(t *testing.T) {
@@ -233,10 +284,9 @@ func TestPostUserWithInvalidEmailFormat(t *testing.T) {
 
 			assert.Equal(t, 400, w.Code, "Expected 400 for email: %s", tc.email)
 
-			var response map[string]string
-			err := json.Unmarshal(w.Body.Bytes(), &response)
-			assert.Nil(t, err)
-			assert.NotEmpty(t, response["error"])
+			body := errorBody(t, w)
+			assert.Equal(t, httperr.CodeInvalidEmail, body.Code)
+			assert.NotEmpty(t, body.Message)
 		})
 	}
 }
@@ -261,10 +311,9 @@ func TestPostUserWithTooLongEmail(t *testing.T) {
 
 	assert.Equal(t, 400, w.Code)
 
-	var response map[string]string
-	err := json.Unmarshal(w.Body.Bytes(), &response)
-	assert.Nil(t, err)
-	assert.Equal(t, "email is too long (max 254 characters)", response["error"])
+	body := errorBody(t, w)
+	assert.Equal(t, httperr.CodeEmailTooLong, body.Code)
+	assert.Equal(t, "email is too long (max 254 characters)", body.Message)
 }
 
 func TestPostUserWithEmailNormalization(t *testing.T) {
@@ -291,3 +340,336 @@ func TestPostUserWithEmailNormalization(t *testing.T) {
 	assert.Nil(t, result.Error)
 	assert.Equal(t, "test@example.com", user.Email)
 }
+
+func TestPostUserIsUnverifiedAndSendsVerificationEmail(t *testing.T) {
+	db := setupTestDB()
+	mailer := newFakeMailer()
+	router := setupRouterWithMailer(db, mailer)
+
+	userPost := UserPost{Email: "pending@example.com"}
+	jsonValue, _ := json.Marshal(userPost)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/users", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+
+	var user User
+	result := db.Where("email = ?", "pending@example.com").First(&user)
+	assert.Nil(t, result.Error)
+	assert.False(t, user.Verified)
+	assert.NotEmpty(t, user.VerificationToken)
+	assert.NotEmpty(t, mailer.linkFor("pending@example.com"))
+}
+
+func TestVerifyUserWithValidToken(t *testing.T) {
+	db := setupTestDB()
+	mailer := newFakeMailer()
+	router := setupRouterWithMailer(db, mailer)
+
+	userPost := UserPost{Email: "verifyme@example.com"}
+	jsonValue, _ := json.Marshal(userPost)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/users", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+
+	var user User
+	assert.Nil(t, db.Where("email = ?", "verifyme@example.com").First(&user).Error)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/users/verify?token="+user.VerificationToken, nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+
+	assert.Nil(t, db.Where("email = ?", "verifyme@example.com").First(&user).Error)
+	assert.True(t, user.Verified)
+	assert.NotNil(t, user.VerifiedAt)
+	assert.Empty(t, user.VerificationToken)
+}
+
+func TestVerifyUserWithInvalidToken(t *testing.T) {
+	db := setupTestDB()
+	router := setupRouter(db)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/users/verify?token=does-not-exist", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 400, w.Code)
+
+	body := errorBody(t, w)
+	assert.Equal(t, httperr.CodeInvalidRequest, body.Code)
+}
+
+func TestResendVerificationForUnverifiedUser(t *testing.T) {
+	db := setupTestDB()
+	mailer := newFakeMailer()
+	router := setupRouterWithMailer(db, mailer)
+
+	userPost := UserPost{Email: "resend@example.com"}
+	jsonValue, _ := json.Marshal(userPost)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/users", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+
+	var original User
+	assert.Nil(t, db.Where("email = ?", "resend@example.com").First(&original).Error)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/users/resend-verification", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+}
+
+func TestResendVerificationIsRateLimitedPerEmailAcrossEndpoints(t *testing.T) {
+	db := setupTestDB()
+	mailer := newFakeMailer()
+	router := setupRouterWithMailer(db, mailer)
+
+	userPost := UserPost{Email: "throttled@example.com"}
+	jsonValue, _ := json.Marshal(userPost)
+
+	// Signup counts as the first of ratelimit.PerEmailRate's 3 requests/hour.
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/users", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+
+	// Two resends exhaust the remaining budget.
+	for i := 0; i < 2; i++ {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("POST", "/users/resend-verification", bytes.NewBuffer(jsonValue))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, 200, w.Code)
+	}
+
+	// The fourth request for this address, resend or not, is over budget.
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/users/resend-verification", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 429, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	body := errorBody(t, w)
+	assert.Equal(t, httperr.CodeRateLimited, body.Code)
+}
+
+func TestResendVerificationForAlreadyVerifiedUser(t *testing.T) {
+	db := setupTestDB()
+	router := setupRouter(db)
+
+	now := time.Now()
+	db.Create(&User{Email: "done@example.com", Verified: true, VerifiedAt: &now})
+
+	body, _ := json.Marshal(UserPost{Email: "done@example.com"})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/users/resend-verification", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	// Must not reveal that the address is already verified: same generic
+	// 200 an unregistered address gets.
+	assert.Equal(t, 200, w.Code)
+
+	var response map[string]string
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "if the address is registered, a verification email has been sent", response["message"])
+}
+
+func createTestAdmin(t *testing.T, db *gorm.DB, email, password string) User {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	assert.Nil(t, err)
+
+	admin := User{Email: email, PasswordHash: string(hash), Role: RoleAdmin, Verified: true}
+	assert.Nil(t, db.Create(&admin).Error)
+	return admin
+}
+
+func TestGetUsersRequiresAdminRole(t *testing.T) {
+	db := setupTestDB()
+	router := setupRouter(db)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/users", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 401, w.Code)
+
+	body := errorBody(t, w)
+	assert.Equal(t, httperr.CodeUnauthorized, body.Code)
+}
+
+func TestGetUsersWithValidAdminToken(t *testing.T) {
+	db := setupTestDB()
+	router := setupRouter(db)
+
+	db.Create(&User{Email: "member@example.com", Verified: true})
+	admin := createTestAdmin(t, db, "admin@example.com", "hunter2")
+
+	accessToken, err := testAuthManager.IssueAccessToken(admin.ID, admin.Email, admin.Role)
+	assert.Nil(t, err)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/users", nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+
+	var users []User
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &users))
+	assert.Len(t, users, 2)
+}
+
+func TestGetUsersRejectsNonAdminToken(t *testing.T) {
+	db := setupTestDB()
+	router := setupRouter(db)
+
+	accessToken, err := testAuthManager.IssueAccessToken(1, "member@example.com", RoleUser)
+	assert.Nil(t, err)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/users", nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 401, w.Code)
+}
+
+func TestAdminLoginAndRefresh(t *testing.T) {
+	db := setupTestDB()
+	router := setupRouter(db)
+	createTestAdmin(t, db, "admin@example.com", "hunter2")
+
+	loginBody, _ := json.Marshal(map[string]string{"email": "admin@example.com", "password": "hunter2"})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/admin/login", bytes.NewBuffer(loginBody))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+
+	var loginResp struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &loginResp))
+	assert.NotEmpty(t, loginResp.AccessToken)
+	assert.NotEmpty(t, loginResp.RefreshToken)
+
+	refreshBody, _ := json.Marshal(map[string]string{"refresh_token": loginResp.RefreshToken})
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/admin/refresh", bytes.NewBuffer(refreshBody))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+
+	var refreshResp struct {
+		AccessToken string `json:"access_token"`
+	}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &refreshResp))
+	assert.NotEmpty(t, refreshResp.AccessToken)
+}
+
+func TestAdminLoginWithWrongPassword(t *testing.T) {
+	db := setupTestDB()
+	router := setupRouter(db)
+	createTestAdmin(t, db, "admin@example.com", "hunter2")
+
+	loginBody, _ := json.Marshal(map[string]string{"email": "admin@example.com", "password": "wrong"})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/admin/login", bytes.NewBuffer(loginBody))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 401, w.Code)
+
+	body := errorBody(t, w)
+	assert.Equal(t, httperr.CodeUnauthorized, body.Code)
+}
+
+func TestPostUserWithDatabaseErrorReturnsInternalServerError(t *testing.T) {
+	db := setupTestDB()
+	router := setupRouter(db)
+
+	sqlDB, err := db.DB()
+	assert.Nil(t, err)
+	assert.Nil(t, sqlDB.Close())
+
+	userPost := UserPost{Email: "closed-db@example.com"}
+	jsonValue, _ := json.Marshal(userPost)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/users", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 500, w.Code)
+
+	body := errorBody(t, w)
+	assert.Equal(t, httperr.CodeInternalServerError, body.Code)
+	assert.NotContains(t, body.Message, "sql")
+}
+
+// stubMXResolver is a minimal emailcheck.Resolver for exercising the MX
+// deliverability check without touching the network.
+type stubMXResolver struct {
+	mx map[string][]*net.MX
+}
+
+func (r *stubMXResolver) LookupMX(ctx context.Context, domain string) ([]*net.MX, error) {
+	return r.mx[domain], nil
+}
+
+func (r *stubMXResolver) LookupHost(ctx context.Context, domain string) ([]string, error) {
+	return nil, &net.DNSError{IsNotFound: true}
+}
+
+func TestValidatorRejectsDisposableDomain(t *testing.T) {
+	disposable, err := emailcheck.NewDisposableList("")
+	assert.Nil(t, err)
+	validator := newEmailValidator(true, false, disposable, nil)
+
+	_, err = validator.Validate(context.Background(), "user@mailinator.com")
+	ve, ok := err.(*ValidationError)
+	assert.True(t, ok)
+	assert.Equal(t, httperr.CodeEmailDisposable, ve.Code)
+}
+
+func TestValidatorAcceptsNonDisposableDomain(t *testing.T) {
+	disposable, err := emailcheck.NewDisposableList("")
+	assert.Nil(t, err)
+	validator := newEmailValidator(true, false, disposable, nil)
+
+	email, err := validator.Validate(context.Background(), "user@example.com")
+	assert.Nil(t, err)
+	assert.Equal(t, "user@example.com", email)
+}
+
+func TestValidatorRejectsUndeliverableDomain(t *testing.T) {
+	resolver := &stubMXResolver{mx: map[string][]*net.MX{}}
+	checker := emailcheck.NewDeliverabilityChecker(resolver, time.Second)
+	validator := newEmailValidator(false, true, nil, checker)
+
+	_, err := validator.Validate(context.Background(), "user@no-mx.example")
+	ve, ok := err.(*ValidationError)
+	assert.True(t, ok)
+	assert.Equal(t, httperr.CodeEmailUndeliverable, ve.Code)
+}
+
+func TestValidatorAcceptsDeliverableDomain(t *testing.T) {
+	resolver := &stubMXResolver{mx: map[string][]*net.MX{
+		"example.com": {{Host: "mail.example.com", Pref: 10}},
+	}}
+	checker := emailcheck.NewDeliverabilityChecker(resolver, time.Second)
+	validator := newEmailValidator(false, true, nil, checker)
+
+	email, err := validator.Validate(context.Background(), "user@example.com")
+	assert.Nil(t, err)
+	assert.Equal(t, "user@example.com", email)
+}
@@ -0,0 +1,29 @@
+// Command genspecsum writes the sha256 checksum of openapi/openapi.yaml to
+// openapi/spec.sum. Run via `go generate ./...` after editing the spec;
+// openapi_test.go fails CI if the committed checksum is stale.
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// go generate runs this with the working directory set to the package
+// containing the //go:generate directive, i.e. openapi/.
+func main() {
+	specPath := filepath.Join("openapi.yaml")
+	sumPath := filepath.Join("spec.sum")
+
+	data, err := os.ReadFile(specPath)
+	if err != nil {
+		log.Fatalf("genspecsum: read %s: %v", specPath, err)
+	}
+
+	sum := sha256.Sum256(data)
+	if err := os.WriteFile(sumPath, []byte(hex.EncodeToString(sum[:])+"\n"), 0o644); err != nil {
+		log.Fatalf("genspecsum: write %s: %v", sumPath, err)
+	}
+}
@@ -0,0 +1,107 @@
+// Package ratelimit builds the limiter.Store the API rate limits run on and
+// provides the per-email throttling middleware layered on top of it.
+package ratelimit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	limiter "github.com/ulule/limiter/v3"
+	"github.com/ulule/limiter/v3/drivers/store/memory"
+	limiterredis "github.com/ulule/limiter/v3/drivers/store/redis"
+
+	"github.com/machidevdev/dorado-be/httperr"
+)
+
+// GlobalIPRate is the per-client-IP policy; the API applies it to POST /users.
+var GlobalIPRate = limiter.Rate{Period: time.Minute, Limit: 10}
+
+// PerEmailRate throttles how often a single email address can trigger a
+// verification email, whether from signup or from a resend request.
+var PerEmailRate = limiter.Rate{Period: time.Hour, Limit: 3}
+
+// NewStore builds a limiter.Store backed by Redis when redisURL is non-empty,
+// so limits are shared across replicas and survive deploys. With an empty
+// redisURL it falls back to an in-memory store, which is fine for local dev
+// and single-replica setups but resets on every restart.
+func NewStore(redisURL string) (limiter.Store, error) {
+	if redisURL == "" {
+		return memory.NewStore(), nil
+	}
+
+	opt, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("ratelimit: parse REDIS_URL: %w", err)
+	}
+
+	return limiterredis.NewStoreWithOptions(redis.NewClient(opt), limiter.StoreOptions{
+		Prefix: "dorado-be",
+	})
+}
+
+// EmailNormalizer extracts and normalizes the email address the per-email
+// policy should key on. Callers pass their own email validator (e.g. the
+// API's normalizeEmailSyntax) so this package doesn't need to know its rules.
+type EmailNormalizer func(string) (string, error)
+
+// PerEmail returns middleware that rate limits requests by the normalized
+// email address found in the JSON request body, under the given rate. It
+// peeks c.Request.Body via io.ReadAll and restores it so the handler can
+// still bind the body normally. Requests with no parseable email are left
+// to the handler's own validation and pass through unthrottled.
+func PerEmail(store limiter.Store, rate limiter.Rate, normalize EmailNormalizer) gin.HandlerFunc {
+	instance := limiter.New(store, rate)
+
+	return func(c *gin.Context) {
+		// Request.Body is nil for requests built without one (e.g. a bare
+		// http.NewRequest GET); ReadAll on a nil io.Reader panics, so guard it.
+		var body []byte
+		if c.Request.Body != nil {
+			var err error
+			body, err = io.ReadAll(c.Request.Body)
+			if err != nil {
+				httperr.Abort(c, 400, httperr.CodeInvalidRequest, "failed to read request body", "")
+				return
+			}
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		var payload struct {
+			Email string `json:"email"`
+		}
+		if err := json.Unmarshal(body, &payload); err != nil || payload.Email == "" {
+			c.Next()
+			return
+		}
+
+		email, err := normalize(payload.Email)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Second)
+		defer cancel()
+
+		result, err := instance.Get(ctx, "email:"+email)
+		if err != nil {
+			httperr.Abort(c, 500, httperr.CodeInternalServerError, "internal server error", "")
+			return
+		}
+
+		if result.Reached {
+			c.Header("Retry-After", strconv.FormatInt(result.Reset-time.Now().Unix(), 10))
+			httperr.Abort(c, 429, httperr.CodeRateLimited, "too many requests for this email, please try again later", "")
+			return
+		}
+
+		c.Next()
+	}
+}
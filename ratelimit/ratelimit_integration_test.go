@@ -0,0 +1,63 @@
+//go:build integration
+
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	limiter "github.com/ulule/limiter/v3"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// startRedis launches a disposable Redis container for the duration of the
+// test and returns its connection URL.
+func startRedis(t *testing.T) string {
+	t.Helper()
+	ctx := context.Background()
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "redis:7-alpine",
+			ExposedPorts: []string{"6379/tcp"},
+			WaitingFor:   wait.ForLog("Ready to accept connections"),
+		},
+		Started: true,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = container.Terminate(ctx) })
+
+	host, err := container.Host(ctx)
+	require.NoError(t, err)
+	port, err := container.MappedPort(ctx, "6379")
+	require.NoError(t, err)
+
+	return fmt.Sprintf("redis://%s:%s", host, port.Port())
+}
+
+func TestRedisStoreEnforcesLimitAcrossInstances(t *testing.T) {
+	redisURL := startRedis(t)
+
+	storeA, err := NewStore(redisURL)
+	require.NoError(t, err)
+	storeB, err := NewStore(redisURL)
+	require.NoError(t, err)
+
+	rate := limiter.Rate{Period: time.Minute, Limit: 1}
+	ctx := context.Background()
+
+	result, err := limiter.New(storeA, rate).Get(ctx, "email:shared@example.com")
+	require.NoError(t, err)
+	assert.False(t, result.Reached)
+
+	// A second store instance pointed at the same Redis must see the limit
+	// the first instance already consumed.
+	result, err = limiter.New(storeB, rate).Get(ctx, "email:shared@example.com")
+	require.NoError(t, err)
+	assert.True(t, result.Reached)
+}
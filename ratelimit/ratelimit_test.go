@@ -0,0 +1,115 @@
+package ratelimit
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	limiter "github.com/ulule/limiter/v3"
+	"github.com/ulule/limiter/v3/drivers/store/memory"
+)
+
+func normalizeLower(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return "", assert.AnError
+	}
+	return email, nil
+}
+
+func newTestRouter(rate limiter.Rate) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/test", PerEmail(memory.NewStore(), rate, normalizeLower), func(c *gin.Context) {
+		var body struct {
+			Email string `json:"email"`
+		}
+		c.ShouldBindJSON(&body)
+		c.JSON(200, gin.H{"message": "ok"})
+	})
+	return r
+}
+
+func TestNewStoreFallsBackToMemoryWithoutRedisURL(t *testing.T) {
+	store, err := NewStore("")
+	assert.Nil(t, err)
+	assert.NotNil(t, store)
+}
+
+func TestNewStoreRejectsInvalidRedisURL(t *testing.T) {
+	_, err := NewStore("not-a-valid-url")
+	assert.NotNil(t, err)
+}
+
+func TestPerEmailAllowsRequestsUnderTheLimit(t *testing.T) {
+	router := newTestRouter(limiter.Rate{Period: time.Hour, Limit: 3})
+
+	for i := 0; i < 3; i++ {
+		body, _ := json.Marshal(map[string]string{"email": "same@example.com"})
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/test", bytes.NewBuffer(body))
+		router.ServeHTTP(w, req)
+		assert.Equal(t, 200, w.Code)
+	}
+}
+
+func TestPerEmailRejectsRequestsOverTheLimitWithRetryAfter(t *testing.T) {
+	router := newTestRouter(limiter.Rate{Period: time.Hour, Limit: 1})
+
+	body, _ := json.Marshal(map[string]string{"email": "over@example.com"})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/test", bytes.NewBuffer(body))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/test", bytes.NewBuffer(body))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 429, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+}
+
+func TestPerEmailTracksEachAddressIndependently(t *testing.T) {
+	router := newTestRouter(limiter.Rate{Period: time.Hour, Limit: 1})
+
+	first, _ := json.Marshal(map[string]string{"email": "a@example.com"})
+	second, _ := json.Marshal(map[string]string{"email": "b@example.com"})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/test", bytes.NewBuffer(first))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/test", bytes.NewBuffer(second))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+}
+
+func TestPerEmailAllowsNilBodyRequest(t *testing.T) {
+	router := newTestRouter(limiter.Rate{Period: time.Hour, Limit: 3})
+
+	req, err := http.NewRequest("POST", "/test", nil)
+	assert.Nil(t, err)
+	assert.Nil(t, req.Body, "precondition: http.NewRequest with a nil body leaves Body nil")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+}
+
+func TestPerEmailLeavesUnparseableBodyToHandler(t *testing.T) {
+	router := newTestRouter(limiter.Rate{Period: time.Hour, Limit: 0})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/test", bytes.NewBuffer([]byte(`not json`)))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+}